@@ -0,0 +1,24 @@
+package storage
+
+// BackupSegment describes one incremental segment of a backup chain:
+// the closed-open range of change numbers [FromChangeNumber,
+// ToChangeNumber) it covers, the file it is stored in relative to the
+// manifest's own path, and the CRC32C of that file's contents.
+type BackupSegment struct {
+	FromChangeNumber uint64
+	ToChangeNumber   uint64
+	File             string
+	CRC32C           uint32
+}
+
+// BackupManifest is the on-disk record of a backup chain produced by
+// BackupTo followed by zero or more calls to BackupIncrementalTo. It
+// tracks the base snapshot's change number and the ordered chain of
+// incremental Segments layered on top of it, so that RestoreToPoint
+// can locate the base, apply segments in order, and stop replay at the
+// requested change number.
+type BackupManifest struct {
+	BaseChangeNumber uint64
+	BaseFile         string
+	Segments         []BackupSegment
+}
@@ -0,0 +1,44 @@
+package etcd
+
+import (
+	"os"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+)
+
+// BackupTo writes the entire keyspace, gob-encoded the same way
+// GetSnapshot does, to the single file at path.
+func (s *Store) BackupTo(path string) error {
+	rc, err := s.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.ReadFrom(rc); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// RestoreFrom replaces the cluster's entire keyspace with the contents
+// of the file at path, previously written by BackupTo. The Store
+// does not implement ChangePropagator or ChangeApplier, so those are
+// returned nil, the same way a caller type-asserting for them on any
+// other non-replicating KVStore would see.
+func (s *Store) RestoreFrom(path string) (storage.KVStore, storage.Backupable, storage.ChangePropagator, storage.ChangeApplier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if err := s.PutSnapshot(f); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return s, s, nil, nil, nil
+}
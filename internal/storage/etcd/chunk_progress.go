@@ -0,0 +1,47 @@
+package etcd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// chunkProgress is the sidecar file PutSnapshotChunk persists after
+// every chunk it accepts, so that a transfer broken partway through
+// can be resumed by calling GetSnapshotChunks again with startSequence
+// one past LastSequence instead of restarting from scratch.
+type chunkProgress struct {
+	// LastSequence is the Sequence of the last chunk verified and
+	// applied, or -1 if no chunk has been accepted yet.
+	LastSequence int64
+	// Checksum is that chunk's SHA256, i.e. the head of the checksum
+	// chain splitChunks builds.
+	Checksum [32]byte
+}
+
+func newChunkProgress() chunkProgress {
+	return chunkProgress{LastSequence: -1}
+}
+
+func readChunkProgress(path string) (chunkProgress, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newChunkProgress(), nil
+		}
+		return chunkProgress{}, err
+	}
+	var p chunkProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return chunkProgress{}, err
+	}
+	return p, nil
+}
+
+func writeChunkProgress(path string, p chunkProgress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,65 @@
+package etcd
+
+import (
+	"crypto/sha256"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+)
+
+// defaultChunkSize is used when GetSnapshotChunks is called with a
+// non-positive chunkSize.
+const defaultChunkSize = 1 << 20 // 1 MiB
+
+// splitChunks divides data into pieces of at most chunkSize bytes,
+// numbered from zero, and wraps each into a storage.SnapshotChunk.
+// Each chunk's SHA256 chains into the previous chunk's: chunk N's
+// checksum is sha256(chunk N-1's checksum ++ chunk N's data), with an
+// all-zero checksum standing in for chunk -1. That makes the checksum
+// trivially resumable across a restart - verifying chunk N only needs
+// chunk N-1's 32-byte checksum, not the hasher's internal state -
+// while still detecting a missing or corrupted chunk anywhere earlier
+// in the sequence.
+//
+// Only chunks with sequence >= startSequence are returned, but the
+// chain is still walked from sequence zero so that a resumed transfer
+// verifies against the same checksums a fresh transfer would have
+// produced.
+func splitChunks(data []byte, chunkSize int, startSequence, changeNumber uint64) []storage.SnapshotChunk {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	var chunks []storage.SnapshotChunk
+	var prevSum [32]byte
+	var seq uint64
+	for offset := 0; offset < len(data); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		piece := data[offset:end]
+		sum := chainChecksum(prevSum, piece)
+		if seq >= startSequence {
+			chunks = append(chunks, storage.SnapshotChunk{
+				Sequence:     seq,
+				Data:         append([]byte(nil), piece...),
+				SHA256:       sum,
+				ChangeNumber: changeNumber,
+			})
+		}
+		prevSum = sum
+		seq++
+	}
+	return chunks
+}
+
+// chainChecksum computes the next link of the checksum chain
+// splitChunks builds, given the previous chunk's checksum (or the zero
+// value for the first chunk) and this chunk's data.
+func chainChecksum(prevSum [32]byte, data []byte) [32]byte {
+	h := sha256.New()
+	h.Write(prevSum[:])
+	h.Write(data)
+	var sum [32]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
@@ -0,0 +1,80 @@
+package etcd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitChunksCoversAllData(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 25)
+	chunks := splitChunks(data, 10, 0, 7)
+
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	var reassembled []byte
+	for i, c := range chunks {
+		if c.Sequence != uint64(i) {
+			t.Fatalf("chunk %d has Sequence %d", i, c.Sequence)
+		}
+		if c.ChangeNumber != 7 {
+			t.Fatalf("chunk %d has ChangeNumber %d, want 7", i, c.ChangeNumber)
+		}
+		reassembled = append(reassembled, c.Data...)
+	}
+	if !bytes.Equal(reassembled, data) {
+		t.Fatalf("reassembled data does not match input")
+	}
+}
+
+func TestSplitChunksResumeProducesSameChecksums(t *testing.T) {
+	data := bytes.Repeat([]byte("y"), 25)
+	full := splitChunks(data, 10, 0, 1)
+	resumed := splitChunks(data, 10, 1, 1)
+
+	if len(resumed) != len(full)-1 {
+		t.Fatalf("got %d resumed chunks, want %d", len(resumed), len(full)-1)
+	}
+	for i, c := range resumed {
+		want := full[i+1]
+		if c.SHA256 != want.SHA256 {
+			t.Fatalf("resumed chunk %d checksum differs from a fresh transfer's", c.Sequence)
+		}
+	}
+}
+
+func TestChainChecksumDetectsCorruption(t *testing.T) {
+	var zero [32]byte
+	sum := chainChecksum(zero, []byte("chunk data"))
+	if chainChecksum(zero, []byte("chunk dat4")) == sum {
+		t.Fatalf("checksum did not change for corrupted chunk data")
+	}
+}
+
+func TestChunkProgressRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "progress.json")
+
+	initial, err := readChunkProgress(path)
+	if err != nil {
+		t.Fatalf("readChunkProgress on missing file: %v", err)
+	}
+	if initial.LastSequence != -1 {
+		t.Fatalf("LastSequence = %d, want -1 for a fresh transfer", initial.LastSequence)
+	}
+
+	initial.LastSequence = 3
+	initial.Checksum = chainChecksum([32]byte{}, []byte("chunk 3"))
+	if err := writeChunkProgress(path, initial); err != nil {
+		t.Fatalf("writeChunkProgress: %v", err)
+	}
+
+	got, err := readChunkProgress(path)
+	if err != nil {
+		t.Fatalf("readChunkProgress: %v", err)
+	}
+	if got.LastSequence != 3 || got.Checksum != initial.Checksum {
+		t.Fatalf("got %+v, want %+v", got, initial)
+	}
+}
@@ -0,0 +1,114 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+)
+
+func (s *Store) progressFilePath() string {
+	return filepath.Join(s.chunkStateDir, "progress.json")
+}
+
+func (s *Store) accumulationFilePath() string {
+	return filepath.Join(s.chunkStateDir, "accumulating.gob")
+}
+
+// GetSnapshotChunks snapshots the entire keyspace, the same way
+// GetSnapshot does, and streams it back as a sequence of checksummed
+// SnapshotChunks starting at startSequence, so that a caller resuming
+// a broken transfer can pick up from the last chunk it verified rather
+// than re-fetching the whole snapshot.
+func (s *Store) GetSnapshotChunks(startSequence uint64, chunkSize int) (<-chan storage.SnapshotChunk, <-chan error) {
+	chunkCh := make(chan storage.SnapshotChunk)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(chunkCh)
+		defer close(errCh)
+		rev, pairs, err := s.scanAllAtRevision(context.Background())
+		if err != nil {
+			errCh <- err
+			return
+		}
+		data, err := encodeSegment(pairs)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		for _, chunk := range splitChunks(data, chunkSize, startSequence, uint64(rev)) {
+			chunkCh <- chunk
+		}
+	}()
+	return chunkCh, errCh
+}
+
+// PutSnapshotChunk verifies chunk is the next one expected, per the
+// sidecar progress file at s.chunkStateDir, and appends its data to
+// the in-flight accumulation file there. Once enough chunks have been
+// accumulated to decode a complete snapshot, it is applied via
+// PutSnapshot and the resume state is cleared so the next transfer
+// starts clean.
+func (s *Store) PutSnapshotChunk(chunk storage.SnapshotChunk) error {
+	if err := os.MkdirAll(s.chunkStateDir, 0755); err != nil {
+		return err
+	}
+	progress, err := readChunkProgress(s.progressFilePath())
+	if err != nil {
+		return err
+	}
+	wantSequence := uint64(progress.LastSequence + 1)
+	if chunk.Sequence != wantSequence {
+		return fmt.Errorf("etcd: out-of-order snapshot chunk: got sequence %d, want %d", chunk.Sequence, wantSequence)
+	}
+	if want := chainChecksum(progress.Checksum, chunk.Data); want != chunk.SHA256 {
+		return fmt.Errorf("etcd: snapshot chunk %d failed checksum verification", chunk.Sequence)
+	}
+
+	accumPath := s.accumulationFilePath()
+	f, err := os.OpenFile(accumPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(chunk.Data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	progress.LastSequence = int64(chunk.Sequence)
+	progress.Checksum = chunk.SHA256
+	if err := writeChunkProgress(s.progressFilePath(), progress); err != nil {
+		return err
+	}
+
+	return s.tryApplyAccumulatedSnapshot(accumPath)
+}
+
+// tryApplyAccumulatedSnapshot attempts to decode the bytes accumulated
+// so far as a complete snapshot. A partial transfer fails to decode,
+// which is expected and not an error: PutSnapshotChunk simply waits
+// for more chunks. Once decoding succeeds the snapshot is applied and
+// the accumulation/progress files are removed, readying the Store for
+// the next transfer.
+func (s *Store) tryApplyAccumulatedSnapshot(accumPath string) error {
+	data, err := ioutil.ReadFile(accumPath)
+	if err != nil {
+		return err
+	}
+	pairs, err := decodeSegment(data)
+	if err != nil {
+		return nil
+	}
+	if err := s.Put(pairs...); err != nil {
+		return err
+	}
+	os.Remove(accumPath)
+	os.Remove(s.progressFilePath())
+	return nil
+}
@@ -0,0 +1,172 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+const baseSegmentFile = "base.gob"
+
+// BackupIncrementalTo treats path as a directory holding a
+// BackupManifest plus the base and incremental segment files it
+// references. The first call for a given path writes a fresh base
+// snapshot and manifest; every later call appends one incremental
+// segment covering the changes committed since sinceChangeNumber,
+// sourced directly from etcd's own revision log via Watch rather than
+// through ChangePropagator.LoadChanges - this adapter does not
+// implement ChangePropagator/ChangeApplier, since serverpb.ChangeRecord
+// is not defined anywhere in this snapshot to construct instances of.
+// The etcd cluster's revision number is used as the change number
+// throughout.
+func (s *Store) BackupIncrementalTo(path string, sinceChangeNumber uint64) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	manifest, err := readManifest(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		return s.writeBaseBackup(path)
+	}
+
+	ctx := context.Background()
+	toRev, err := s.currentRevision(ctx)
+	if err != nil {
+		return err
+	}
+	if int64(sinceChangeNumber) >= toRev {
+		return nil
+	}
+	pairs, err := s.watchChanges(ctx, int64(sinceChangeNumber)+1, toRev)
+	if err != nil {
+		return err
+	}
+	data, err := encodeSegment(pairs)
+	if err != nil {
+		return err
+	}
+	segFile := fmt.Sprintf("segment-%d-%d.gob", sinceChangeNumber, toRev)
+	if err := ioutil.WriteFile(segmentPath(path, segFile), data, 0644); err != nil {
+		return err
+	}
+	manifest.Segments = append(manifest.Segments, storage.BackupSegment{
+		FromChangeNumber: sinceChangeNumber,
+		ToChangeNumber:   uint64(toRev),
+		File:             segFile,
+		CRC32C:           checksumCRC32C(data),
+	})
+	return writeManifest(path, manifest)
+}
+
+func (s *Store) writeBaseBackup(path string) error {
+	rev, pairs, err := s.scanAllAtRevision(context.Background())
+	if err != nil {
+		return err
+	}
+	data, err := encodeSegment(pairs)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(segmentPath(path, baseSegmentFile), data, 0644); err != nil {
+		return err
+	}
+	return writeManifest(path, &storage.BackupManifest{BaseChangeNumber: uint64(rev), BaseFile: baseSegmentFile})
+}
+
+// watchChanges collects every Put/Delete event committed in
+// [fromRev, toRev], as a slice of pairs with a nil Value standing in
+// for a deletion.
+func (s *Store) watchChanges(ctx context.Context, fromRev, toRev int64) ([]*serverpb.KVPair, error) {
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var pairs []*serverpb.KVPair
+	watchCh := s.client.Watch(wctx, "", clientv3.WithPrefix(), clientv3.WithRev(fromRev))
+	for wresp := range watchCh {
+		if err := wresp.Err(); err != nil {
+			return nil, err
+		}
+		for _, ev := range wresp.Events {
+			if ev.Kv.ModRevision > toRev {
+				continue
+			}
+			if ev.Type == clientv3.EventTypeDelete {
+				pairs = append(pairs, &serverpb.KVPair{Key: ev.Kv.Key})
+				continue
+			}
+			pair, err := decodeRecord(ev.Kv.Key, ev.Kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, pair)
+		}
+		if wresp.Header.Revision >= toRev {
+			return pairs, nil
+		}
+	}
+	return pairs, nil
+}
+
+// RestoreToPoint applies the base snapshot at path followed by its
+// incremental segments in order, each verified against its recorded
+// CRC32C before being applied, stopping as soon as targetChangeNumber
+// has been reached.
+func (s *Store) RestoreToPoint(path string, targetChangeNumber uint64) (storage.KVStore, storage.Backupable, storage.ChangePropagator, storage.ChangeApplier, error) {
+	manifest, err := readManifest(path)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	basePairs, err := decodeSegmentFile(segmentPath(path, manifest.BaseFile))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if err := s.Put(basePairs...); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	applied := manifest.BaseChangeNumber
+
+	for _, seg := range manifest.Segments {
+		if applied >= targetChangeNumber {
+			break
+		}
+		data, err := ioutil.ReadFile(segmentPath(path, seg.File))
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if checksumCRC32C(data) != seg.CRC32C {
+			return nil, nil, nil, nil, fmt.Errorf("etcd: segment %s failed CRC32C verification", seg.File)
+		}
+		pairs, err := decodeSegment(data)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if err := s.applyChangePairs(pairs); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		applied = seg.ToChangeNumber
+	}
+	return s, s, nil, nil, nil
+}
+
+func (s *Store) applyChangePairs(pairs []*serverpb.KVPair) error {
+	for _, pair := range pairs {
+		if pair.Value == nil {
+			if err := s.Delete(pair.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.Put(pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
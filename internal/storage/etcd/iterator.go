@@ -0,0 +1,74 @@
+package etcd
+
+import "github.com/flipkart-incubator/dkv/pkg/serverpb"
+
+// sliceIterator is a storage.Iterator over a slice of pairs already
+// fetched from etcd in one range Get. Since a single Get comfortably
+// covers the key ranges this adapter deals with, there is no need to
+// page results in from the server lazily.
+type sliceIterator struct {
+	pairs []*serverpb.KVPair
+	pos   int
+	err   error
+}
+
+// newSliceIterator builds a sliceIterator over pairs, restricted to
+// keys with the given prefix (if non-nil), starting at startKey (if
+// non-nil) and, if end is non-nil, stopping before the first key >=
+// end. pairs must already be sorted in ascending key order.
+func newSliceIterator(pairs []*serverpb.KVPair, prefix, startKey, end []byte) *sliceIterator {
+	filtered := make([]*serverpb.KVPair, 0, len(pairs))
+	for _, pair := range pairs {
+		if prefix != nil && !hasPrefix(pair.Key, prefix) {
+			continue
+		}
+		if startKey != nil && lessKey(pair.Key, startKey) {
+			continue
+		}
+		if end != nil && !lessKey(pair.Key, end) {
+			break
+		}
+		filtered = append(filtered, pair)
+	}
+	return &sliceIterator{pairs: filtered, pos: -1}
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.pos++
+	return it.pos < len(it.pairs)
+}
+
+func (it *sliceIterator) Key() []byte {
+	return it.pairs[it.pos].Key
+}
+
+func (it *sliceIterator) Value() []byte {
+	return it.pairs[it.pos].Value
+}
+
+func (it *sliceIterator) Err() error {
+	return it.err
+}
+
+func (it *sliceIterator) Close() error {
+	return nil
+}
+
+func hasPrefix(key, prefix []byte) bool {
+	if len(key) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if key[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func lessKey(a, b []byte) bool {
+	return string(a) < string(b)
+}
@@ -0,0 +1,41 @@
+package etcd
+
+// splitKeyRanges partitions n approximately equal, non-overlapping
+// [start, end) ranges out of sortedKeys, which must already be sorted
+// in ascending order. The first range's start and the last range's end
+// are nil, meaning "from the beginning of the keyspace" and "through
+// its end" respectively, so the ranges returned always cover the
+// entire keyspace even as it grows or shrinks between the split and
+// whatever later reads it.
+func splitKeyRanges(sortedKeys [][]byte, n int) [][2][]byte {
+	if n <= 0 {
+		n = 1
+	}
+	if len(sortedKeys) == 0 {
+		return [][2][]byte{{nil, nil}}
+	}
+	if n > len(sortedKeys) {
+		n = len(sortedKeys)
+	}
+
+	ranges := make([][2][]byte, 0, n)
+	batch := len(sortedKeys) / n
+	remainder := len(sortedKeys) % n
+
+	var start []byte
+	idx := 0
+	for i := 0; i < n; i++ {
+		size := batch
+		if i < remainder {
+			size++
+		}
+		idx += size
+		var end []byte
+		if i < n-1 {
+			end = sortedKeys[idx]
+		}
+		ranges = append(ranges, [2][]byte{start, end})
+		start = end
+	}
+	return ranges
+}
@@ -0,0 +1,44 @@
+package etcd
+
+import "testing"
+
+func keys(ss ...string) [][]byte {
+	out := make([][]byte, len(ss))
+	for i, s := range ss {
+		out[i] = []byte(s)
+	}
+	return out
+}
+
+func TestSplitKeyRangesCoversWholeKeyspace(t *testing.T) {
+	ranges := splitKeyRanges(keys("a", "b", "c", "d", "e"), 3)
+
+	if len(ranges) != 3 {
+		t.Fatalf("got %d ranges, want 3", len(ranges))
+	}
+	if ranges[0][0] != nil {
+		t.Fatalf("first range start = %q, want nil", ranges[0][0])
+	}
+	if ranges[len(ranges)-1][1] != nil {
+		t.Fatalf("last range end = %q, want nil", ranges[len(ranges)-1][1])
+	}
+	for i := 0; i < len(ranges)-1; i++ {
+		if string(ranges[i][1]) != string(ranges[i+1][0]) {
+			t.Fatalf("range %d end %q does not match range %d start %q", i, ranges[i][1], i+1, ranges[i+1][0])
+		}
+	}
+}
+
+func TestSplitKeyRangesFewerKeysThanRequestedRanges(t *testing.T) {
+	ranges := splitKeyRanges(keys("a", "b"), 5)
+	if len(ranges) != 2 {
+		t.Fatalf("got %d ranges, want 2 (one per key, capped)", len(ranges))
+	}
+}
+
+func TestSplitKeyRangesEmptyKeyspace(t *testing.T) {
+	ranges := splitKeyRanges(nil, 4)
+	if len(ranges) != 1 || ranges[0][0] != nil || ranges[0][1] != nil {
+		t.Fatalf("got %+v, want a single [nil, nil) range for an empty keyspace", ranges)
+	}
+}
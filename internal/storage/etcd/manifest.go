@@ -0,0 +1,82 @@
+package etcd
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"hash/crc32"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumCRC32C returns the CRC32C (Castagnoli) checksum of data, the
+// same algorithm storage.BackupSegment.CRC32C records.
+func checksumCRC32C(data []byte) uint32 {
+	return crc32.Checksum(data, crc32cTable)
+}
+
+func manifestPath(path string) string {
+	return filepath.Join(path, "manifest.json")
+}
+
+func segmentPath(path, file string) string {
+	return filepath.Join(path, file)
+}
+
+// readManifest loads the BackupManifest at path, written by a
+// previous BackupIncrementalTo call.
+func readManifest(path string) (*storage.BackupManifest, error) {
+	data, err := ioutil.ReadFile(manifestPath(path))
+	if err != nil {
+		return nil, err
+	}
+	var m storage.BackupManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// writeManifest persists m as the BackupManifest at path.
+func writeManifest(path string, m *storage.BackupManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifestPath(path), data, 0644)
+}
+
+// encodeSegment gob-encodes pairs for storage as a base or incremental
+// segment file. A nil Value marks the pair as a deletion rather than a
+// put, since a segment also has to carry forward keys that were
+// removed since the previous change number.
+func encodeSegment(pairs []*serverpb.KVPair) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pairs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSegment reverses encodeSegment.
+func decodeSegment(data []byte) ([]*serverpb.KVPair, error) {
+	var pairs []*serverpb.KVPair
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&pairs); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// decodeSegmentFile reads and decodes the segment file at path.
+func decodeSegmentFile(path string) ([]*serverpb.KVPair, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSegment(data)
+}
@@ -0,0 +1,76 @@
+package etcd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+func TestSegmentRoundTrip(t *testing.T) {
+	pairs := []*serverpb.KVPair{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b")},
+	}
+	data, err := encodeSegment(pairs)
+	if err != nil {
+		t.Fatalf("encodeSegment: %v", err)
+	}
+	got, err := decodeSegment(data)
+	if err != nil {
+		t.Fatalf("decodeSegment: %v", err)
+	}
+	if len(got) != len(pairs) {
+		t.Fatalf("got %d pairs, want %d", len(got), len(pairs))
+	}
+	if string(got[0].Key) != "a" || string(got[0].Value) != "1" {
+		t.Fatalf("pair 0 = %+v, want a=1", got[0])
+	}
+	if string(got[1].Key) != "b" || got[1].Value != nil {
+		t.Fatalf("pair 1 = %+v, want b=<nil> (a deletion)", got[1])
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	want := &storage.BackupManifest{
+		BaseChangeNumber: 7,
+		BaseFile:         baseSegmentFile,
+		Segments: []storage.BackupSegment{
+			{FromChangeNumber: 7, ToChangeNumber: 12, File: "segment-7-12.gob", CRC32C: 42},
+		},
+	}
+	if err := writeManifest(dir, want); err != nil {
+		t.Fatalf("writeManifest: %v", err)
+	}
+	got, err := readManifest(dir)
+	if err != nil {
+		t.Fatalf("readManifest: %v", err)
+	}
+	if got.BaseChangeNumber != want.BaseChangeNumber || got.BaseFile != want.BaseFile {
+		t.Fatalf("got base %+v, want %+v", got, want)
+	}
+	if len(got.Segments) != 1 || got.Segments[0] != want.Segments[0] {
+		t.Fatalf("got segments %+v, want %+v", got.Segments, want.Segments)
+	}
+}
+
+func TestChecksumCRC32CDetectsCorruption(t *testing.T) {
+	data := []byte("incremental segment contents")
+	sum := checksumCRC32C(data)
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[0] ^= 0xff
+	if checksumCRC32C(corrupted) == sum {
+		t.Fatalf("checksum did not change after corrupting a byte")
+	}
+}
+
+func TestSegmentPathJoinsUnderBackupDir(t *testing.T) {
+	got := segmentPath("/backups/store-1", "segment-0-5.gob")
+	want := filepath.Join("/backups/store-1", "segment-0-5.gob")
+	if got != want {
+		t.Fatalf("segmentPath = %q, want %q", got, want)
+	}
+}
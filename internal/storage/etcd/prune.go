@@ -0,0 +1,44 @@
+package etcd
+
+import (
+	"context"
+	"time"
+)
+
+// Prune scans the keyspace in key order and deletes, up to maxKeys,
+// every key whose ExpireTS (set via Txn's Op.ExpireTS) is non-zero and
+// older than olderThan. maxKeys bounds evictions, not the scan itself,
+// so a sweep keeps walking past long runs of non-expired keys instead
+// of giving up before it ever reaches an expired one. Every eviction is
+// an ordinary etcd Delete, so it is already captured by the cluster's
+// own revision log - BackupIncrementalTo's Watch-sourced segments see
+// it the same way they see any other delete, with no separate
+// replication path needed for this adapter.
+func (s *Store) Prune(olderThan time.Time, maxKeys int) (scanned int, evicted int, err error) {
+	ctx := context.Background()
+	_, kvs, err := s.scanRaw(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cutoff := uint64(olderThan.Unix())
+	for _, kv := range kvs {
+		if maxKeys > 0 && evicted >= maxKeys {
+			break
+		}
+		scanned++
+
+		r, err := decodeRecordValue(kv.Value)
+		if err != nil {
+			return scanned, evicted, err
+		}
+		if r.ExpireTS == 0 || r.ExpireTS >= cutoff {
+			continue
+		}
+		if _, err := s.client.Delete(ctx, string(kv.Key)); err != nil {
+			return scanned, evicted, err
+		}
+		evicted++
+	}
+	return scanned, evicted, nil
+}
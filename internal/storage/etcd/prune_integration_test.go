@@ -0,0 +1,54 @@
+package etcd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+)
+
+// TestStorePruneEvictsExpiredKeys is an integration test of Prune
+// against a real etcd cluster, rather than only against storage's
+// fakePrunable. It is skipped unless DKV_ETCD_TEST_ENDPOINTS names at
+// least one reachable etcd endpoint, since no etcd cluster is
+// available in every environment this suite runs in.
+func TestStorePruneEvictsExpiredKeys(t *testing.T) {
+	endpoints := os.Getenv("DKV_ETCD_TEST_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("DKV_ETCD_TEST_ENDPOINTS not set; skipping etcd integration test")
+	}
+
+	kvStore, err := New(storage.Config{Params: map[string]interface{}{
+		"endpoints": strings.Split(endpoints, ","),
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer kvStore.Close()
+
+	store := kvStore.(*Store)
+	txnStore := kvStore.(storage.Transactional)
+
+	key := []byte("prune-integration-test-key")
+	expireTS := uint64(time.Now().Add(-time.Hour).Unix())
+	op := storage.Op{Type: storage.OpPut, Key: key, Value: []byte("v"), ExpireTS: expireTS}
+	if _, err := txnStore.Txn([]storage.Op{op}); err != nil {
+		t.Fatalf("Txn put: %v", err)
+	}
+
+	scanned, evicted, err := store.Prune(time.Now(), 100)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if evicted == 0 {
+		t.Fatalf("Prune evicted 0 keys, want at least the expired test key")
+	}
+	if scanned < evicted {
+		t.Fatalf("scanned %d < evicted %d", scanned, evicted)
+	}
+	if _, err := store.Get(key); err == nil {
+		t.Fatalf("expired key %q still present after Prune", key)
+	}
+}
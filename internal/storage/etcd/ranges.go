@@ -0,0 +1,87 @@
+package etcd
+
+import (
+	"context"
+	"encoding/gob"
+	"os"
+	"sync"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// OrderedIterate walks the keyspace restricted to [start, end) in
+// ascending key order, sourced from the same sorted range Get Iterate
+// and GetSnapshot already use.
+func (s *Store) OrderedIterate(start, end []byte) storage.Iterator {
+	pairs, err := s.scanAll(context.Background())
+	if err != nil {
+		return &sliceIterator{err: err}
+	}
+	return newSliceIterator(pairs, nil, start, end)
+}
+
+// SplitKeyRanges partitions the current keyspace into n approximately
+// equal-sized, non-overlapping [start, end) key ranges.
+func (s *Store) SplitKeyRanges(n int) ([][2][]byte, error) {
+	pairs, err := s.scanAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	keys := make([][]byte, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = pair.Key
+	}
+	return splitKeyRanges(keys, n), nil
+}
+
+// RestoreFromRanges restores the store from the single backup file at
+// path, the same format BackupTo writes, except every given range is
+// applied concurrently on its own goroutine rather than as one
+// sequential pass.
+func (s *Store) RestoreFromRanges(path string, ranges [][2][]byte) (storage.KVStore, storage.Backupable, storage.ChangePropagator, storage.ChangeApplier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer f.Close()
+
+	var pairs []*serverpb.KVPair
+	if err := gob.NewDecoder(f).Decode(&pairs); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(ranges))
+	for _, r := range ranges {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rangePairs := make([]*serverpb.KVPair, 0)
+			for _, pair := range pairs {
+				if r[0] != nil && lessKey(pair.Key, r[0]) {
+					continue
+				}
+				if r[1] != nil && !lessKey(pair.Key, r[1]) {
+					continue
+				}
+				rangePairs = append(rangePairs, pair)
+			}
+			if len(rangePairs) == 0 {
+				return
+			}
+			if err := s.Put(rangePairs...); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+	return s, s, nil, nil, nil
+}
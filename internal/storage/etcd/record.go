@@ -0,0 +1,46 @@
+package etcd
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// record is what is actually stored as an etcd value. etcd only knows
+// about raw bytes, so every key's expiry has to travel alongside its
+// value rather than as an etcd-native concept.
+type record struct {
+	Value    []byte
+	ExpireTS uint64
+}
+
+// encodeRecord gob-encodes r for storage as an etcd value.
+func encodeRecord(r record) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRecord reverses encodeRecord and reattaches key, which is not
+// itself part of the encoded record.
+func decodeRecord(key, data []byte) (*serverpb.KVPair, error) {
+	r, err := decodeRecordValue(data)
+	if err != nil {
+		return nil, err
+	}
+	return &serverpb.KVPair{Key: key, Value: r.Value}, nil
+}
+
+// decodeRecordValue reverses encodeRecord without discarding
+// ExpireTS, which decodeRecord does since serverpb.KVPair has no field
+// for it; Prune needs ExpireTS to decide which keys have expired.
+func decodeRecordValue(data []byte) (record, error) {
+	var r record
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&r); err != nil {
+		return record{}, err
+	}
+	return r, nil
+}
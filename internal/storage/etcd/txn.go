@@ -0,0 +1,101 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/flipkart-incubator/dkv/internal/storage"
+	"github.com/flipkart-incubator/dkv/pkg/serverpb"
+)
+
+// Txn maps a batch of storage.Ops onto a single etcd Txn: every Op's
+// Predicate, if set, becomes one of the Txn's If clauses (etcd ANDs
+// them together, so the whole batch is rejected unless every
+// predicate holds), and the mutating Ops become the Txn's Then
+// clause. OpGet results are read back out of the committed response
+// rather than a separate round trip, so the whole batch - checks,
+// writes and reads - is one atomic etcd transaction.
+func (s *Store) Txn(ops []storage.Op) (storage.TxnResult, error) {
+	var cmps []clientv3.Cmp
+	var thenOps []clientv3.Op
+	getAt := make(map[int]int, len(ops))
+
+	for i, op := range ops {
+		if op.Predicate != nil {
+			cmp, err := predicateCmp(op.Key, op.Predicate)
+			if err != nil {
+				return storage.TxnResult{}, err
+			}
+			cmps = append(cmps, cmp)
+		}
+		switch op.Type {
+		case storage.OpPut, storage.OpCAS:
+			data, err := encodeRecord(record{Value: op.Value, ExpireTS: op.ExpireTS})
+			if err != nil {
+				return storage.TxnResult{}, err
+			}
+			thenOps = append(thenOps, clientv3.OpPut(string(op.Key), string(data)))
+		case storage.OpDelete:
+			thenOps = append(thenOps, clientv3.OpDelete(string(op.Key)))
+		case storage.OpGet:
+			getAt[len(thenOps)] = i
+			thenOps = append(thenOps, clientv3.OpGet(string(op.Key)))
+		default:
+			return storage.TxnResult{}, fmt.Errorf("etcd: unsupported op type %v", op.Type)
+		}
+	}
+
+	txn := s.client.Txn(context.Background())
+	if len(cmps) > 0 {
+		txn = txn.If(cmps...)
+	}
+	resp, err := txn.Then(thenOps...).Commit()
+	if err != nil {
+		return storage.TxnResult{}, err
+	}
+	if !resp.Succeeded {
+		return storage.TxnResult{}, fmt.Errorf("etcd: txn aborted, a predicate did not hold")
+	}
+
+	results := make([]*serverpb.KVPair, len(ops))
+	for respIdx, opIdx := range getAt {
+		rangeResp := resp.Responses[respIdx].GetResponseRange()
+		if rangeResp == nil || len(rangeResp.Kvs) == 0 {
+			continue
+		}
+		pair, err := decodeRecord(ops[opIdx].Key, rangeResp.Kvs[0].Value)
+		if err != nil {
+			return storage.TxnResult{}, err
+		}
+		results[opIdx] = pair
+	}
+	return storage.TxnResult{Results: results, ChangeNumber: uint64(resp.Header.Revision)}, nil
+}
+
+// predicateCmp translates a storage.Predicate into the etcd Cmp that
+// must hold for key.
+func predicateCmp(key []byte, p *storage.Predicate) (clientv3.Cmp, error) {
+	switch p.Kind {
+	case storage.PredicateNone:
+		return clientv3.Compare(clientv3.CreateRevision(string(key)), ">=", 0), nil
+	case storage.PredicateExists:
+		return clientv3.Compare(clientv3.CreateRevision(string(key)), ">", 0), nil
+	case storage.PredicateNotExists:
+		return clientv3.Compare(clientv3.CreateRevision(string(key)), "=", 0), nil
+	case storage.PredicateVersionEquals:
+		return clientv3.Compare(clientv3.Version(string(key)), "=", int64(p.Version)), nil
+	case storage.PredicateValueEquals:
+		// ExpireTS is not part of the comparison: Op carries it
+		// separately from Predicate.Value, and a key's expiry
+		// shouldn't change whether its value is considered equal.
+		data, err := encodeRecord(record{Value: p.Value})
+		if err != nil {
+			return clientv3.Cmp{}, err
+		}
+		return clientv3.Compare(clientv3.Value(string(key)), "=", string(data)), nil
+	default:
+		return clientv3.Cmp{}, fmt.Errorf("etcd: unsupported predicate kind %v", p.Kind)
+	}
+}
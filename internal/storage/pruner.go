@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flipkart-incubator/dkv/internal/stats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrunerStat holds the Prometheus instrumentation emitted by a Pruner
+// as it sweeps expired keys, alongside the per-engine Stat already
+// kept for the regular request path.
+type PrunerStat struct {
+	Scanned      prometheus.Counter
+	Evicted      prometheus.Counter
+	Errors       prometheus.Counter
+	BatchLatency prometheus.Histogram
+}
+
+// NewPrunerStat registers and returns the PrunerStat for the given
+// engine.
+func NewPrunerStat(registry prometheus.Registerer, engine string) *PrunerStat {
+	scanned := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: stats.Namespace,
+		Name:      fmt.Sprintf("storage_pruner_scanned_%s", engine),
+		Help:      fmt.Sprintf("Number of keys scanned by the %s TTL pruner", engine),
+	})
+	evicted := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: stats.Namespace,
+		Name:      fmt.Sprintf("storage_pruner_evicted_%s", engine),
+		Help:      fmt.Sprintf("Number of expired keys evicted by the %s TTL pruner", engine),
+	})
+	errs := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: stats.Namespace,
+		Name:      fmt.Sprintf("storage_pruner_errors_%s", engine),
+		Help:      fmt.Sprintf("Number of failed sweep batches by the %s TTL pruner", engine),
+	})
+	batchLatency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: stats.Namespace,
+		Name:      fmt.Sprintf("storage_pruner_batch_latency_%s", engine),
+		Help:      fmt.Sprintf("Latency of a single %s TTL pruner batch deletion", engine),
+	})
+	registry.MustRegister(scanned, evicted, errs, batchLatency)
+	return &PrunerStat{Scanned: scanned, Evicted: evicted, Errors: errs, BatchLatency: batchLatency}
+}
+
+// PrunerOpts configures the behaviour of a Pruner.
+type PrunerOpts struct {
+	// Interval between successive sweeps.
+	Interval time.Duration
+	// BatchSize bounds how many expired keys a single sweep deletes.
+	BatchSize int
+	// QPS caps how many batches the Pruner issues per second, bounding
+	// its impact on foreground read/write traffic. Zero means
+	// unlimited.
+	QPS int
+	// WriteStallCheck, when non-nil, is consulted before every sweep;
+	// if it returns true the sweep is skipped so that pruning never
+	// contends with and prolongs an in-progress write stall.
+	WriteStallCheck func() bool
+}
+
+// Pruner periodically scans a Prunable store for expired keys, via its
+// Prune method, and deletes them in bounded batches. It runs its sweep
+// loop on its own goroutine once Start is called, until Stop is
+// invoked.
+type Pruner struct {
+	store     Prunable
+	opts      PrunerOpts
+	stat      *PrunerStat
+	stopCh    chan struct{}
+	lastSweep time.Time
+}
+
+// NewPruner creates a Pruner for the given store. stat may be nil, in
+// which case the Pruner runs without emitting metrics.
+func NewPruner(store Prunable, opts PrunerOpts, stat *PrunerStat) *Pruner {
+	return &Pruner{store: store, opts: opts, stat: stat, stopCh: make(chan struct{})}
+}
+
+// Start begins the periodic sweep loop on a new goroutine and returns
+// immediately.
+func (p *Pruner) Start() {
+	go p.run()
+}
+
+// Stop terminates the sweep loop started by Start.
+func (p *Pruner) Stop() {
+	close(p.stopCh)
+}
+
+func (p *Pruner) run() {
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.sweep()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *Pruner) sweep() {
+	if p.opts.WriteStallCheck != nil && p.opts.WriteStallCheck() {
+		return
+	}
+	p.throttle()
+	start := time.Now()
+	scanned, evicted, err := p.store.Prune(time.Now(), p.opts.BatchSize)
+	p.lastSweep = start
+	if err != nil {
+		if p.stat != nil {
+			p.stat.Errors.Inc()
+		}
+		return
+	}
+	if p.stat == nil {
+		return
+	}
+	p.stat.Scanned.Add(float64(scanned))
+	p.stat.Evicted.Add(float64(evicted))
+	p.stat.BatchLatency.Observe(time.Since(start).Seconds())
+}
+
+// throttle blocks, if needed, so that successive batches are no more
+// frequent than PrunerOpts.QPS per second.
+func (p *Pruner) throttle() {
+	if p.opts.QPS <= 0 || p.lastSweep.IsZero() {
+		return
+	}
+	minGap := time.Second / time.Duration(p.opts.QPS)
+	if elapsed := time.Since(p.lastSweep); elapsed < minGap {
+		time.Sleep(minGap - elapsed)
+	}
+}
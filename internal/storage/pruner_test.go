@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakePrunable struct {
+	scanned, evicted int
+	err              error
+	calls            int
+}
+
+func (f *fakePrunable) Prune(olderThan time.Time, maxKeys int) (int, int, error) {
+	f.calls++
+	return f.scanned, f.evicted, f.err
+}
+
+func newTestPrunerStat() *PrunerStat {
+	return NewPrunerStat(prometheus.NewRegistry(), "test")
+}
+
+func TestPrunerSweepRecordsScannedAndEvicted(t *testing.T) {
+	store := &fakePrunable{scanned: 10, evicted: 3}
+	stat := newTestPrunerStat()
+	p := NewPruner(store, PrunerOpts{BatchSize: 100}, stat)
+
+	p.sweep()
+
+	if store.calls != 1 {
+		t.Fatalf("calls = %d, want 1", store.calls)
+	}
+	if got := testutil.ToFloat64(stat.Scanned); got != 10 {
+		t.Fatalf("Scanned = %v, want 10", got)
+	}
+	if got := testutil.ToFloat64(stat.Evicted); got != 3 {
+		t.Fatalf("Evicted = %v, want 3", got)
+	}
+}
+
+func TestPrunerSweepSkipsWhenWriteStalled(t *testing.T) {
+	store := &fakePrunable{scanned: 10, evicted: 3}
+	p := NewPruner(store, PrunerOpts{BatchSize: 100, WriteStallCheck: func() bool { return true }}, nil)
+
+	p.sweep()
+
+	if store.calls != 0 {
+		t.Fatalf("calls = %d, want 0 during a write stall", store.calls)
+	}
+}
+
+func TestPrunerSweepRecordsErrorsWithoutScannedOrEvicted(t *testing.T) {
+	store := &fakePrunable{err: errors.New("boom")}
+	stat := newTestPrunerStat()
+	p := NewPruner(store, PrunerOpts{BatchSize: 100}, stat)
+
+	p.sweep()
+
+	if got := testutil.ToFloat64(stat.Errors); got != 1 {
+		t.Fatalf("Errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(stat.Scanned); got != 0 {
+		t.Fatalf("Scanned = %v, want 0 on error", got)
+	}
+}
+
+func TestPrunerThrottleWaitsBetweenBatches(t *testing.T) {
+	store := &fakePrunable{}
+	p := NewPruner(store, PrunerOpts{BatchSize: 1, QPS: 1000}, nil)
+
+	p.sweep()
+	start := time.Now()
+	p.sweep()
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond {
+		t.Fatalf("expected throttle to wait at least 1ms between batches, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,39 @@
+package storage
+
+import "fmt"
+
+// Config captures the backend specific settings needed to construct a
+// KVStore via the registry. Params holds engine specific settings
+// (e.g. etcd endpoints, Consul ACL token) that each Factory interprets
+// on its own.
+type Config struct {
+	Engine string
+	Params map[string]interface{}
+}
+
+// Factory constructs a KVStore from the given Config. Implementations
+// that also support backup/restore or replication should return a
+// value that additionally satisfies Backupable, ChangePropagator
+// and/or ChangeApplier so callers can obtain those traits via a type
+// assertion.
+type Factory func(cfg Config) (KVStore, error)
+
+var registry = make(map[string]Factory)
+
+// Register associates the given backend name with a Factory so it can
+// later be instantiated by name via New. Backend packages are expected
+// to call this from an init function.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New looks up the Factory registered under name and invokes it with
+// the given Config, returning an error if no backend has been
+// registered under that name.
+func New(name string, cfg Config) (KVStore, error) {
+	factory, present := registry[name]
+	if !present {
+		return nil, fmt.Errorf("storage: no backend registered under name %q", name)
+	}
+	return factory(cfg)
+}
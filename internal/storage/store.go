@@ -37,6 +37,51 @@ func NewStat(registry prometheus.Registerer, engine string) *Stat {
 
 type MemoryUsage interface{}
 
+// IterationOptions controls the range a KVStore.Iterate or
+// RangeIterable.OrderedIterate call covers. The zero value iterates
+// the entire keyspace.
+type IterationOptions struct {
+	// KeyPrefix, if non-nil, restricts iteration to keys sharing this
+	// prefix.
+	KeyPrefix []byte
+	// StartKey, if non-nil, seeks iteration to begin at this key
+	// rather than the start of the keyspace (or of KeyPrefix, if both
+	// are set).
+	StartKey []byte
+}
+
+// Iterator walks a sequence of key/value pairs produced by
+// KVStore.Iterate or RangeIterable.OrderedIterate. Callers must call
+// Next before the first Key/Value access, and must call Close once
+// done with the Iterator regardless of whether iteration ran to
+// completion or stopped early on an error.
+type Iterator interface {
+	// Next advances the Iterator to the next pair, returning false
+	// once the Iterator is exhausted or has encountered an error;
+	// callers must check Err afterwards to tell the two apart.
+	Next() bool
+	Key() []byte
+	Value() []byte
+	// Err returns the first error encountered during iteration, if
+	// any.
+	Err() error
+	io.Closer
+}
+
+// SnapshotChunk is a single fixed-size piece of a keyspace streamed via
+// GetSnapshotChunks/PutSnapshotChunk. Sequence numbers start at zero
+// and increase by one per chunk; SHA256 is a rolling checksum computed
+// over all chunks up to and including this one, letting a receiver
+// verify it has not missed or corrupted an earlier chunk before
+// persisting its own progress. ChangeNumber is the source's latest
+// committed change number as of when the chunk was produced.
+type SnapshotChunk struct {
+	Sequence     uint64
+	Data         []byte
+	SHA256       [32]byte
+	ChangeNumber uint64
+}
+
 // A KVStore represents the key value store that provides
 // the underlying storage implementation for the various
 // DKV operations.
@@ -74,6 +119,141 @@ type KVStore interface {
 	GetMemoryUsage() (MemoryUsage, error)
 }
 
+// A Prunable represents the capability of the underlying store to
+// delete expired keys on demand, outside of the periodic sweep a
+// Pruner drives. Kept separate from KVStore so existing
+// implementations are not forced to take it on; callers discover
+// support via a type assertion.
+type Prunable interface {
+	// Prune deletes, on demand, up to maxKeys keys whose expireTS is
+	// older than olderThan, using the engine's native batch delete
+	// API. It returns the number of keys scanned and the number
+	// actually evicted. Like Txn, each eviction emits a change record
+	// so slaves stay consistent.
+	Prune(olderThan time.Time, maxKeys int) (scanned int, evicted int, err error)
+}
+
+// A Transactional represents the capability of the underlying store to
+// apply a batch of Ops as a single, indivisible change. It is kept
+// separate from KVStore, rather than folded into it, so that existing
+// KVStore implementations keep compiling until they opt in; callers
+// discover support the same way they already do for Backupable,
+// ChangePropagator and ChangeApplier: via a type assertion on a
+// KVStore value.
+type Transactional interface {
+	// Txn atomically applies the given sequence of Ops as a single,
+	// indivisible change. Every Op whose Predicate does not hold is
+	// treated as a failure of the whole batch: nothing is applied and
+	// an error is returned. On success the batch is recorded and
+	// replicated as a single ChangeRecord so that slaves apply it
+	// atomically too.
+	Txn(ops []Op) (TxnResult, error)
+}
+
+// A StreamingSnapshotStore represents the capability of the underlying
+// store to transfer its snapshot as a resumable sequence of
+// checksummed chunks, as an alternative to the single opaque stream
+// GetSnapshot/PutSnapshot provide. Kept separate from KVStore so
+// existing implementations are not forced to take it on; callers
+// discover support via a type assertion.
+type StreamingSnapshotStore interface {
+	// GetSnapshotChunks streams the entire keyspace as a sequence of
+	// SnapshotChunks of approximately chunkSize bytes each, starting
+	// from startSequence. Unlike GetSnapshot, the result can be
+	// resumed: a caller that lost the stream partway through can call
+	// GetSnapshotChunks again with startSequence set to one past the
+	// last chunk it verified, rather than restarting from scratch.
+	GetSnapshotChunks(startSequence uint64, chunkSize int) (<-chan SnapshotChunk, <-chan error)
+	// PutSnapshotChunk verifies and ingests a single SnapshotChunk
+	// previously obtained from GetSnapshotChunks. Callers are expected
+	// to persist their own progress (e.g. in a sidecar file keyed by
+	// Sequence) so that a broken transfer can be resumed by re-calling
+	// GetSnapshotChunks from the last successfully applied Sequence.
+	PutSnapshotChunk(chunk SnapshotChunk) error
+}
+
+// A RangeIterable represents the capability of the underlying store to
+// iterate its keyspace in key order and to partition that keyspace
+// into approximately equal-sized ranges, e.g. for parallel restore or
+// sharded replication catch-up. Kept separate from KVStore so existing
+// implementations are not forced to take it on; callers discover
+// support via a type assertion.
+type RangeIterable interface {
+	// OrderedIterate iterates over the keyspace in ascending
+	// lexicographic key order, restricted to [start, end). A nil end
+	// means iterate through to the end of the keyspace. Unlike
+	// Iterate, callers can rely on key order, which is what makes
+	// OrderedIterate suitable for range based sharding of restore and
+	// replication work.
+	OrderedIterate(start, end []byte) Iterator
+	// SplitKeyRanges partitions the keyspace into n approximately
+	// equal-sized, non-overlapping [start, end) key ranges based on
+	// the engine's own size statistics. The returned ranges can be fed
+	// to OrderedIterate, or to RangedRestorable.RestoreFromRanges to
+	// parallelize restore.
+	SplitKeyRanges(n int) ([][2][]byte, error)
+}
+
+// OpType identifies the kind of operation a single Op within a Txn
+// performs.
+type OpType int
+
+const (
+	OpPut OpType = iota
+	OpGet
+	OpDelete
+	OpCAS
+)
+
+// PredicateKind enumerates the per-key preconditions that can gate
+// whether an Op within a Txn is applied.
+type PredicateKind int
+
+const (
+	// PredicateNone means the Op is unconditional.
+	PredicateNone PredicateKind = iota
+	// PredicateExists requires the key to currently exist.
+	PredicateExists
+	// PredicateNotExists requires the key to currently be absent.
+	PredicateNotExists
+	// PredicateVersionEquals requires the key's current version to
+	// equal Predicate.Version.
+	PredicateVersionEquals
+	// PredicateValueEquals requires the key's current value to equal
+	// Predicate.Value.
+	PredicateValueEquals
+)
+
+// Predicate is an optional per-key precondition attached to an Op. If
+// the predicate does not hold for its Key at commit time, the entire
+// enclosing Txn is rolled back.
+type Predicate struct {
+	Kind    PredicateKind
+	Version uint64
+	Value   []byte
+}
+
+// Op is a single Put, Get, Delete or CAS operation submitted as part
+// of a Txn. Key is required for every OpType; Value and ExpireTS apply
+// to OpPut; Expect applies to OpCAS.
+type Op struct {
+	Type      OpType
+	Key       []byte
+	Value     []byte
+	Expect    []byte
+	ExpireTS  uint64
+	Predicate *Predicate
+}
+
+// TxnResult carries the outcome of a successfully committed Txn: the
+// per-op results in the same order as the submitted Ops (populated for
+// OpGet and OpCAS), and the change number the transaction was
+// committed under.
+type TxnResult struct {
+	Results      []*serverpb.KVPair
+	ChangeNumber uint64
+}
+
 // A Backupable represents the capability of the underlying store
 // to be backed up and restored using filesystem as the medium.
 type Backupable interface {
@@ -94,6 +274,43 @@ type Backupable interface {
 	RestoreFrom(path string) (KVStore, Backupable, ChangePropagator, ChangeApplier, error)
 }
 
+// A RangedRestorable represents the capability of the underlying store
+// to restore disjoint key ranges concurrently. Kept separate from
+// Backupable so existing implementations are not forced to take it on;
+// callers discover support via a type assertion.
+type RangedRestorable interface {
+	// RestoreFromRanges restores the store from the files at `path`,
+	// the same way Backupable.RestoreFrom does, except the given key
+	// `ranges` (typically obtained from RangeIterable.SplitKeyRanges)
+	// are each restored concurrently rather than as a single
+	// sequential pass. Returns the various traits of the newly
+	// restored store upon successful restoration, else an error.
+	RestoreFromRanges(path string, ranges [][2][]byte) (KVStore, Backupable, ChangePropagator, ChangeApplier, error)
+}
+
+// An IncrementalBackupable represents the capability of the underlying
+// store to take and restore incremental, point-in-time backups layered
+// on top of a base Backupable snapshot. Kept separate from Backupable
+// so existing implementations are not forced to take it on; callers
+// discover support via a type assertion.
+type IncrementalBackupable interface {
+	// BackupIncrementalTo writes an incremental backup under the given
+	// `path`, capturing only the changes committed since
+	// `sinceChangeNumber` by sourcing them from the same stream
+	// `ChangePropagator.LoadChanges` uses. The manifest maintained at
+	// `path` records the base snapshot's change number along with the
+	// [from,to] range and CRC32C checksum of every incremental segment,
+	// so that `RestoreToPoint` can later replay them in order.
+	BackupIncrementalTo(path string, sinceChangeNumber uint64) error
+	// RestoreToPoint restores the store from the backup chain rooted at
+	// `path`: the base snapshot is applied first, followed by the
+	// incremental segments in order, stopping as soon as
+	// `targetChangeNumber` has been applied. Returns the various traits
+	// of the newly restored store upon successful restoration, else an
+	// error, mirroring `Backupable.RestoreFrom`.
+	RestoreToPoint(path string, targetChangeNumber uint64) (KVStore, Backupable, ChangePropagator, ChangeApplier, error)
+}
+
 // A ChangePropagator represents the capability of the underlying
 // store from which committed changes can be retrieved for replication
 // purposes. The implementor of this interface assumes the role of a